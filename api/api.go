@@ -0,0 +1,39 @@
+// Package api implements Partisci's read-only HTTP API.
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"partisci/store"
+	"partisci/version"
+)
+
+// Handler returns an http.Handler serving Partisci's HTTP API against s.
+func Handler(s store.UpdateStore) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/advisories", advisoriesHandler(s))
+	return mux
+}
+
+// advisoriesHandler serves GET /api/v1/advisories?app_id=..., returning
+// the Advisories known for that app_id as a JSON array.
+func advisoriesHandler(s store.UpdateStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		appId := r.URL.Query().Get("app_id")
+		if appId == "" {
+			http.Error(w, "app_id is required", http.StatusBadRequest)
+			return
+		}
+		as := s.Advisories(appId)
+		if as == nil {
+			as = []version.Advisory{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(as)
+	}
+}
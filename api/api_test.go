@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"partisci/store/memory"
+	"partisci/version"
+)
+
+func TestAdvisoriesHandler(t *testing.T) {
+	s := memory.New()
+	v := version.Version{App: "app1", Ver: "0.9.0", Host: "a", Warnings: []string{"upgrade to 0.9.7"}}
+	v.Prepare()
+	s.Update(v)
+
+	h := Handler(s)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/advisories?app_id=app1", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, actual: %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected Content-Type application/json, actual: %s", ct)
+	}
+	if body := rec.Body.String(); body == "" || body == "null\n" {
+		t.Errorf("expected a JSON array of advisories, actual: %q", body)
+	}
+}
+
+func TestAdvisoriesHandlerRequiresAppId(t *testing.T) {
+	h := Handler(memory.New())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/advisories", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, actual: %d", rec.Code)
+	}
+}
+
+func TestAdvisoriesHandlerRejectsNonGet(t *testing.T) {
+	h := Handler(memory.New())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/advisories?app_id=app1", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, actual: %d", rec.Code)
+	}
+}
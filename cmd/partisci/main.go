@@ -0,0 +1,124 @@
+// Command partisci runs the Partisci ingest daemon: it listens for
+// Version reports from partisci clients over UDP, stores them in the
+// configured UpdateStore, and serves Partisci's HTTP API over that same
+// store.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"partisci/api"
+	"partisci/notifier"
+	"partisci/store"
+	"partisci/store/memory"
+	"partisci/store/postgres"
+	"partisci/version"
+)
+
+func main() {
+	storeKind := flag.String("store", "memory", `backend to persist Versions in: "memory" or "postgres"`)
+	postgresDSN := flag.String("postgres-dsn", "", "PostgreSQL connection string, required when -store=postgres")
+	addr := flag.String("addr", ":9871", "UDP address to listen for Version reports on")
+	httpAddr := flag.String("http-addr", ":8080", "address to serve Partisci's HTTP API on")
+	notifierKind := flag.String("notifier", "", `backend to send change notifications to: "stdout", "webhook", "email", or "" to disable`)
+	webhookURL := flag.String("notifier-webhook-url", "", "URL to POST JSON events to, required when -notifier=webhook")
+	smtpAddr := flag.String("notifier-smtp-addr", "", "SMTP server address, required when -notifier=email")
+	notifyFrom := flag.String("notifier-from", "", "From address for -notifier=email")
+	notifyTo := flag.String("notifier-to", "", "To address for -notifier=email")
+	trimAfter := flag.Duration("trim-after", 24*time.Hour, "age at which a host's last report is considered silent and trimmed")
+	trimInterval := flag.Duration("trim-interval", 10*time.Minute, "how often to run Trim")
+	flag.Parse()
+
+	s, err := newStore(*storeKind, *postgresDSN)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *notifierKind != "" {
+		n, err := notifier.New(*notifierKind, notifier.Config{
+			WebhookURL: *webhookURL,
+			SMTPAddr:   *smtpAddr,
+			From:       *notifyFrom,
+			To:         *notifyTo,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		hook := notifier.NewHook(n)
+		defer hook.Close()
+		apps := s.Apps()
+		appIds := make([]string, len(apps))
+		for i, as := range apps {
+			appIds[i] = as.AppId
+		}
+		hook.Seed(appIds)
+		s.SetNotificationHook(hook)
+	}
+
+	go trimPeriodically(s, *trimAfter, *trimInterval)
+
+	go func() {
+		log.Fatal(http.ListenAndServe(*httpAddr, api.Handler(s)))
+	}()
+
+	if err := serve(*addr, s); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newStore constructs the UpdateStore named by kind ("memory" or
+// "postgres"), configured from dsn when kind is "postgres".
+func newStore(kind, dsn string) (store.UpdateStore, error) {
+	switch kind {
+	case "memory":
+		return memory.New(), nil
+	case "postgres":
+		return postgres.New(dsn)
+	}
+	return nil, fmt.Errorf("partisci: unknown -store %q", kind)
+}
+
+// trimPeriodically runs s.Trim every interval, removing (and notifying
+// on, via whatever NotificationHook is registered) versions whose last
+// report is older than after. It never returns.
+func trimPeriodically(s store.UpdateStore, after, interval time.Duration) {
+	for range time.Tick(interval) {
+		s.Trim(time.Now().Add(-after))
+	}
+}
+
+// serve listens for UDP packets on addr, parses each as a Version
+// report, and stores it in s. It blocks until a read fails fatally.
+func serve(addr string, s store.UpdateStore) error {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 65536)
+	for {
+		n, remote, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return err
+		}
+		v, err := version.ParsePacket(remote.IP.String(), buf[:n])
+		if err != nil {
+			log.Printf("partisci: malformed packet from %s: %v", remote, err)
+			continue
+		}
+		v.Prepare()
+		if err := s.Update(v); err != nil {
+			log.Printf("partisci: update error: %v", err)
+		}
+	}
+}
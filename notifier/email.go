@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"fmt"
+	"net/smtp"
+)
+
+// Email is a Notifier that sends each Event as a plain-text message via
+// SMTP.
+type Email struct {
+	Addr string
+	From string
+	To   string
+}
+
+// NewEmail returns an Email Notifier that delivers through the SMTP
+// server at addr.
+func NewEmail(addr, from, to string) *Email {
+	return &Email{Addr: addr, From: from, To: to}
+}
+
+// Notify sends e as a plain-text email. It dials addr fresh for every
+// Event; queue already serializes and retries delivery, so Email does
+// not need to hold a connection open.
+func (e *Email) Notify(ev Event) error {
+	msg := fmt.Sprintf("To: %s\r\nFrom: %s\r\nSubject: partisci: %s\r\n\r\n%s\r\n",
+		e.To, e.From, ev.Type, describe(ev))
+	return smtp.SendMail(e.Addr, nil, e.From, []string{e.To}, []byte(msg))
+}
+
+// describe renders e as a one-line human-readable summary for the email
+// body.
+func describe(e Event) string {
+	switch e.Type {
+	case EventVersionChanged:
+		return fmt.Sprintf("%s on %s moved from %s to %s at %s", e.AppId, e.Host, e.PrevVer, e.Ver, e.Time)
+	case EventNewApp:
+		return fmt.Sprintf("new app %s first seen on %s running %s at %s", e.AppId, e.Host, e.Ver, e.Time)
+	case EventHostSilent:
+		return fmt.Sprintf("%s on %s has gone silent (last seen running %s) at %s", e.AppId, e.Host, e.Ver, e.Time)
+	default:
+		return fmt.Sprintf("%+v", e)
+	}
+}
@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"sync"
+
+	"partisci/version"
+)
+
+// Hook turns the raw transitions an UpdateStore observes into Events and
+// hands them off to a queue for asynchronous, retried delivery. Stores
+// register a Hook via their SetNotificationHook method; Hook satisfies
+// store.NotificationHook structurally, so neither package imports the
+// other.
+type Hook struct {
+	queue *queue
+
+	mu       sync.Mutex
+	seenApps map[string]bool
+}
+
+// NewHook wraps n in a bounded, backoff-retrying delivery queue and
+// returns a Hook ready for an UpdateStore to register. Callers backed by
+// a persistent store should call Seed with the app IDs already on
+// record before registering the Hook, so a daemon restart does not
+// re-announce every pre-existing app as new.
+func NewHook(n Notifier) *Hook {
+	return &Hook{queue: newQueue(n), seenApps: make(map[string]bool)}
+}
+
+// Seed marks appIds as already known, so a subsequent Updated for any of
+// them is not mistaken for a brand-new app.
+func (h *Hook) Seed(appIds []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, id := range appIds {
+		h.seenApps[id] = true
+	}
+}
+
+// Updated is called by an UpdateStore's Update after persisting v. prev
+// is the Version previously stored for the same app/host/instance, and
+// existed reports whether one was found. existed is scoped to that one
+// app/host/instance, so it is also false the first time a known app
+// shows up on a new host; Hook tracks app IDs it has seen itself (seeded
+// via Seed, or observed directly) so EventNewApp only fires the first
+// time an app_id is seen at all. A known app's first report on a new
+// host still fires EventVersionChanged, with an empty PrevVer, so that
+// rollout to the new host is not silently dropped.
+func (h *Hook) Updated(prev, v version.Version, existed bool) {
+	h.mu.Lock()
+	isNewApp := !h.seenApps[v.Id]
+	h.seenApps[v.Id] = true
+	h.mu.Unlock()
+
+	switch {
+	case isNewApp:
+		h.queue.push(Event{Type: EventNewApp, AppId: v.Id, Host: v.Host, Ver: v.Ver, Time: v.ExactUpdate})
+	case !existed || prev.Ver != v.Ver:
+		h.queue.push(Event{Type: EventVersionChanged, AppId: v.Id, Host: v.Host, Ver: v.Ver, PrevVer: prev.Ver, Time: v.ExactUpdate})
+	}
+}
+
+// Trimmed is called by Trim for each Version it removes because the
+// host/app pair has gone silent, and queues an EventHostSilent.
+func (h *Hook) Trimmed(v version.Version) {
+	h.queue.push(Event{Type: EventHostSilent, AppId: v.Id, Host: v.Host, Ver: v.Ver, Time: v.ExactUpdate})
+}
+
+// Close stops accepting new Events and waits for delivery of whatever
+// was already queued to finish.
+func (h *Hook) Close() {
+	h.queue.close()
+}
@@ -0,0 +1,65 @@
+// Package notifier watches UpdateStore changes and emits structured
+// events (app/version transitions, new apps, silent hosts) to pluggable
+// backends, so Partisci can page on-call instead of only driving a
+// dashboard. It parallels Clair's ext/notification package: a store
+// reports raw transitions to a Hook, which turns them into Events and
+// hands them to a bounded, backoff-retrying queue so a slow backend
+// cannot block ingest.
+package notifier
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventType categorizes the kind of change an Event reports.
+type EventType string
+
+const (
+	// EventVersionChanged fires when an app on a host transitions to a new Ver.
+	EventVersionChanged EventType = "version_changed"
+	// EventNewApp fires the first time an app_id is seen.
+	EventNewApp EventType = "new_app"
+	// EventHostSilent fires when a host is removed by Trim, i.e. it has
+	// not reported an update in the configured retention window.
+	EventHostSilent EventType = "host_silent"
+)
+
+// Event is a single observed state transition.
+type Event struct {
+	Type    EventType `json:"type"`
+	AppId   string    `json:"app_id,omitempty"`
+	Host    string    `json:"host,omitempty"`
+	Ver     string    `json:"ver,omitempty"`
+	PrevVer string    `json:"prev_ver,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Notifier delivers an Event to a backend, returning an error if
+// delivery failed so queue can retry it.
+type Notifier interface {
+	Notify(e Event) error
+}
+
+// Config holds the settings for whichever backend is selected in New;
+// only the fields relevant to that backend are used.
+type Config struct {
+	WebhookURL string
+	SMTPAddr   string
+	From       string
+	To         string
+}
+
+// New constructs the Notifier backend named by kind ("stdout", "webhook",
+// or "email"), configured from cfg.
+func New(kind string, cfg Config) (Notifier, error) {
+	switch kind {
+	case "stdout":
+		return NewStdout(), nil
+	case "webhook":
+		return NewWebhook(cfg.WebhookURL), nil
+	case "email":
+		return NewEmail(cfg.SMTPAddr, cfg.From, cfg.To), nil
+	}
+	return nil, fmt.Errorf("notifier: unknown backend %q", kind)
+}
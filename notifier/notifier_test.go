@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"partisci/version"
+)
+
+func TestNewUnknownBackend(t *testing.T) {
+	if _, err := New("carrier-pigeon", Config{}); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+}
+
+func TestStdoutNotify(t *testing.T) {
+	var buf bytes.Buffer
+	s := &Stdout{Out: &buf}
+	e := Event{Type: EventNewApp, AppId: "app1", Host: "a", Time: time.Unix(0, 0).UTC()}
+	if err := s.Notify(e); err != nil {
+		t.Fatal(err)
+	}
+
+	var got Event
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != e {
+		t.Errorf("expected %+v, actual %+v", e, got)
+	}
+}
+
+// flakyNotifier fails its first N calls, then succeeds, recording every
+// attempt so tests can assert on retry behavior.
+type flakyNotifier struct {
+	mu       sync.Mutex
+	failFor  int
+	attempts int
+}
+
+func (f *flakyNotifier) Notify(e Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts++
+	if f.attempts <= f.failFor {
+		return errors.New("flaky: not yet")
+	}
+	return nil
+}
+
+// recordingNotifier records every Event it receives, for assertions on
+// what Hook queued.
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (n *recordingNotifier) Notify(e Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, e)
+	return nil
+}
+
+func TestHookNewAppOnlyFiresOncePerAppId(t *testing.T) {
+	n := &recordingNotifier{}
+	h := NewHook(n)
+
+	// app1's first report, on host a: existed is false because this is
+	// the first (app_id, host, instance), and it genuinely is a new app.
+	h.Updated(version.Version{}, version.Version{App: "app1", Host: "a", Ver: "1.0.0"}, false)
+	// app1 reported on a second host: the store also reports existed as
+	// false, since that is scoped per (app_id, host, instance), but app1
+	// is not a new app, so this must report as a version change (its
+	// first appearance on host b) rather than firing EventNewApp again.
+	h.Updated(version.Version{}, version.Version{App: "app1", Host: "b", Ver: "1.0.0"}, false)
+	h.Close()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.events) != 2 {
+		t.Fatalf("expected 2 events, actual: %d (%+v)", len(n.events), n.events)
+	}
+	if n.events[0].Type != EventNewApp || n.events[0].Host != "a" {
+		t.Errorf("expected a new_app event for host a, actual: %+v", n.events[0])
+	}
+	if n.events[1].Type != EventVersionChanged || n.events[1].Host != "b" {
+		t.Errorf("expected a version_changed event for host b, actual: %+v", n.events[1])
+	}
+}
+
+func TestHookSeedSuppressesNewApp(t *testing.T) {
+	n := &recordingNotifier{}
+	h := NewHook(n)
+	h.Seed([]string{"app1-id"})
+
+	h.Updated(version.Version{}, version.Version{Id: "app1-id", App: "app1", Host: "a", Ver: "1.0.0"}, false)
+	h.Close()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.events) != 1 {
+		t.Fatalf("expected 1 event, actual: %d (%+v)", len(n.events), n.events)
+	}
+	if n.events[0].Type != EventVersionChanged {
+		t.Errorf("expected a seeded app's first report to fire version_changed, not new_app, actual: %+v", n.events[0])
+	}
+}
+
+func TestHookRetriesOnFailure(t *testing.T) {
+	orig := backoff
+	backoff = []time.Duration{time.Millisecond, time.Millisecond}
+	defer func() { backoff = orig }()
+
+	n := &flakyNotifier{failFor: 2}
+	h := NewHook(n)
+	h.Updated(version.Version{}, version.Version{App: "app1", Host: "a"}, false)
+	h.Close()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.attempts != 3 {
+		t.Errorf("expected 3 delivery attempts, actual: %d", n.attempts)
+	}
+}
@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"log"
+	"time"
+)
+
+// queueCapacity bounds the number of Events buffered for delivery so a
+// slow or unreachable backend cannot block ingest. Events pushed once
+// the buffer is full are dropped and logged rather than blocking.
+const queueCapacity = 256
+
+// backoff bounds how hard queue retries a failing Notify before giving
+// up on an Event; len(backoff) is the number of retries attempted.
+var backoff = []time.Duration{
+	100 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	30 * time.Second,
+}
+
+// queue delivers Events to a Notifier on a single background goroutine,
+// retrying failed deliveries with backoff.
+type queue struct {
+	events chan Event
+	done   chan struct{}
+}
+
+// newQueue starts a goroutine delivering Events to n and returns a queue
+// ready to accept them via push.
+func newQueue(n Notifier) *queue {
+	q := &queue{
+		events: make(chan Event, queueCapacity),
+		done:   make(chan struct{}),
+	}
+	go q.run(n)
+	return q
+}
+
+// push enqueues e for delivery, dropping it if the queue is full.
+func (q *queue) push(e Event) {
+	select {
+	case q.events <- e:
+	default:
+		log.Printf("notifier: queue full, dropping %s event for %s/%s", e.Type, e.AppId, e.Host)
+	}
+}
+
+func (q *queue) run(n Notifier) {
+	defer close(q.done)
+	for e := range q.events {
+		deliver(n, e)
+	}
+}
+
+// close stops accepting new Events and waits for the delivery goroutine
+// to drain whatever was already queued.
+func (q *queue) close() {
+	close(q.events)
+	<-q.done
+}
+
+// deliver calls n.Notify, retrying with backoff on failure until the
+// backoff schedule is exhausted.
+func deliver(n Notifier, e Event) {
+	err := n.Notify(e)
+	for attempt := 0; err != nil && attempt < len(backoff); attempt++ {
+		time.Sleep(backoff[attempt])
+		err = n.Notify(e)
+	}
+	if err != nil {
+		log.Printf("notifier: giving up on %s event for %s/%s after %d attempts: %v", e.Type, e.AppId, e.Host, len(backoff)+1, err)
+	}
+}
@@ -0,0 +1,28 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Stdout is a Notifier that writes each Event as a line of JSON to Out.
+type Stdout struct {
+	Out io.Writer
+}
+
+// NewStdout returns a Stdout Notifier that writes to os.Stdout.
+func NewStdout() *Stdout {
+	return &Stdout{Out: os.Stdout}
+}
+
+// Notify writes e to Out as a single line of JSON.
+func (s *Stdout) Notify(e Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(s.Out, string(b))
+	return err
+}
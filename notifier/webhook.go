@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Webhook is a Notifier that POSTs each Event as JSON to URL.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhook returns a Webhook Notifier that POSTs to url, with a 10
+// second request timeout.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify POSTs e to w.URL as JSON, returning an error if the request
+// fails or the response status is not 2xx.
+func (w *Webhook) Notify(e Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	resp, err := w.Client.Post(w.URL, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notifier: webhook %s returned status %d", w.URL, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+// Package sharedtest implements shared UpdateStore interface tests
+//
+// The USTest* functions tests should be run by implementations
+// of the UpdateStore interface to ensure compatability between them.
+package sharedtest
+
+import (
+	"partisci/store"
+	"partisci/version"
+	"testing"
+	"time"
+)
+
+func USTestAppSummary(s store.UpdateStore, t *testing.T) {
+	v := version.Version{App: "app1", Ver: "ver", Host: "a"}
+	v.Prepare()
+	s.Update(v)
+
+	if _, ok := s.App("non-existant"); ok {
+		t.Error("got ok for non-existant AppId")
+	}
+	if as, ok := s.App("app1"); ok {
+		if as.HostCount != 1 {
+			t.Error("expected HostCount: 1, actual: ", as.HostCount)
+		}
+	} else {
+		t.Error("missing expected AppId")
+	}
+
+	v2 := version.Version{App: "app1", Ver: "ver", Host: "b"}
+	v2.Prepare()
+	s.Update(v2)
+	if as, ok := s.App("app1"); ok {
+		if as.HostCount != 2 {
+			t.Error("expected HostCount: 2, actual: ", as.HostCount)
+		}
+	}
+}
+
+func USTestHostSummary(s store.UpdateStore, t *testing.T) {
+	v := version.Version{App: "app1", Ver: "ver", Host: "a"}
+	v.Prepare()
+	s.Update(v)
+
+	if _, ok := s.Host("non-existant"); ok {
+		t.Error("got ok for non-existant Host")
+	}
+	if as, ok := s.Host("a"); ok {
+		if as.AppCount != 1 {
+			t.Error("expected AppCount: 1, actual: ", as.AppCount)
+		}
+	} else {
+		t.Error("missing expected Host")
+	}
+
+	v2 := version.Version{App: "app2", Ver: "ver", Host: "a"}
+	v2.Prepare()
+	s.Update(v2)
+	if as, ok := s.Host("a"); ok {
+		if as.AppCount != 2 {
+			t.Error("expected AppCount: 2, actual: ", as.AppCount)
+		}
+	}
+}
+
+func USTestVersionsInRange(s store.UpdateStore, t *testing.T) {
+	v1 := version.Version{App: "app1", Ver: "0.9.0", Host: "a"}
+	v1.Prepare()
+	s.Update(v1)
+
+	v2 := version.Version{App: "app1", Ver: "1.2.0", Host: "b"}
+	v2.Prepare()
+	s.Update(v2)
+
+	v3 := version.Version{App: "app1", Ver: "2.0.0", Host: "c"}
+	v3.Prepare()
+	s.Update(v3)
+
+	v4 := version.Version{App: "app1", Ver: "not-a-semver", Host: "d"}
+	v4.Prepare()
+	s.Update(v4)
+
+	vs := s.VersionsInRange("app1", ">=1.0.0 <2.0.0")
+	if len(vs) != 1 {
+		t.Fatal("expected: 1, actual: ", len(vs))
+	}
+	if vs[0].Host != "b" {
+		t.Error("expected host b, actual: ", vs[0].Host)
+	}
+
+	unparseable := s.UnparseableVersions("app1")
+	if len(unparseable) != 1 {
+		t.Fatal("expected: 1, actual: ", len(unparseable))
+	}
+	if unparseable[0].Host != "d" {
+		t.Error("expected host d, actual: ", unparseable[0].Host)
+	}
+
+	latest, ok := s.Latest("app1")
+	if !ok {
+		t.Fatal("expected ok")
+	}
+	if latest.Host != "c" {
+		t.Error("expected host c, actual: ", latest.Host)
+	}
+}
+
+func USTestAdvisories(s store.UpdateStore, t *testing.T) {
+	v1 := version.Version{App: "app1", Ver: "0.9.0", Host: "a", Warnings: []string{"known memory leak, upgrade to 0.9.7"}}
+	v1.Prepare()
+	s.Update(v1)
+
+	v2 := version.Version{App: "app1", Ver: "0.9.7", Host: "b", Deprecated: false}
+	v2.Prepare()
+	s.Update(v2)
+
+	as := s.Advisories("app1")
+	if len(as) != 2 {
+		t.Fatal("expected: 2, actual: ", len(as))
+	}
+
+	var found bool
+	for _, a := range as {
+		if a.Ver == "0.9.0" {
+			found = true
+			if len(a.Warnings) != 1 {
+				t.Error("expected 1 warning, actual: ", len(a.Warnings))
+			}
+		}
+	}
+	if !found {
+		t.Error("missing advisory for 0.9.0")
+	}
+}
+
+// test Clear() & Update() interactions
+func USTestClearUpdate(s store.UpdateStore, t *testing.T) {
+	if len(s.Versions("", "", "")) > 0 {
+		t.Error("Versions should be empty")
+	}
+	v := *new(version.Version)
+	v.Prepare()
+	s.Update(v)
+	if len(s.Versions("", "", "")) != 1 {
+		t.Error(v.ExactUpdate, v.LastUpdate)
+		t.Error("Versions should have one entry")
+	}
+	s.Clear()
+	if len(s.Versions("", "", "")) > 0 {
+		t.Error("Versions should be empty")
+	}
+	s.Update(v)
+	if len(s.Versions("", "", "")) > 0 {
+		t.Error("updates older than threshold should be discarded")
+	}
+}
+
+func USTestTrim(s store.UpdateStore, t *testing.T) {
+	// setup one version in the future and a few more
+	v1a := version.Version{App: "app1", Ver: "ver", Host: "a"}
+	v1a.Prepare()
+	v1a.ExactUpdate = v1a.ExactUpdate.Add(time.Duration(10 * time.Second))
+	s.Update(v1a)
+
+	v1b := version.Version{App: "app1", Ver: "ver", Host: "b"}
+	v1b.Prepare()
+	s.Update(v1b)
+
+	v2 := version.Version{App: "app2", Ver: "ver", Host: "a"}
+	v2.Prepare()
+	s.Update(v2)
+
+	// sanity check
+	if l := len(s.Versions("", "", "")); l != 3 {
+		t.Fatal("before: version count - expected: 3, actual: ", l)
+	}
+	if l := len(s.Hosts()); l != 2 {
+		t.Fatal("before: host count - expected: 2, actual: ", l)
+	}
+	if l := len(s.Apps()); l != 2 {
+		t.Fatal("before: app count - expected: 2, actual: ", l)
+	}
+
+	// trim every version before 1 second in the future of one version
+	count := s.Trim(v2.ExactUpdate.Add(time.Duration(1 * time.Second)))
+	if count != 2 {
+		t.Fatal("after: trim should have removed 2 versions")
+	}
+	if l := len(s.Versions("", "", "")); l != 1 {
+		t.Fatal("after: version count - expected: 1, actual: ", l)
+	}
+	if l := len(s.Hosts()); l != 1 {
+		t.Fatal("after: host count - expected: 1, actual: ", l)
+	}
+	if l := len(s.Apps()); l != 1 {
+		t.Fatal("after: app count - expected: 1, actual: ", l)
+	}
+
+	// trim every version
+	count = s.Trim(v2.ExactUpdate.Add(time.Duration(20 * time.Second)))
+	if count != 1 {
+		t.Fatal("after all: trim should have removed the last one version")
+	}
+	if l := len(s.Versions("", "", "")); l != 0 {
+		t.Fatal("after all: version count - expected: 0, actual: ", l)
+	}
+	if l := len(s.Hosts()); l != 0 {
+		t.Fatal(s.Hosts())
+		t.Fatal("after all: host count - expected: 0, actual: ", l)
+	}
+	if l := len(s.Apps()); l != 0 {
+		t.Fatal(s.Apps())
+		t.Fatal("after all: app count - expected: 0, actual: ", l)
+	}
+}
+
+// recordingHook is a store.NotificationHook that records the
+// transitions it is called with, for USTestNotificationHook.
+type recordingHook struct {
+	updates []string
+	trims   []string
+}
+
+func (h *recordingHook) Updated(prev, v version.Version, existed bool) {
+	switch {
+	case !existed:
+		h.updates = append(h.updates, "new:"+v.Host)
+	case prev.Ver != v.Ver:
+		h.updates = append(h.updates, "changed:"+v.Host+":"+prev.Ver+"->"+v.Ver)
+	}
+}
+
+func (h *recordingHook) Trimmed(v version.Version) {
+	h.trims = append(h.trims, v.Host)
+}
+
+func USTestNotificationHook(s store.UpdateStore, t *testing.T) {
+	h := &recordingHook{}
+	s.SetNotificationHook(h)
+	defer s.SetNotificationHook(nil)
+
+	v1 := version.Version{App: "app1", Ver: "1.0.0", Host: "a"}
+	v1.Prepare()
+	s.Update(v1)
+
+	v2 := version.Version{App: "app1", Ver: "1.1.0", Host: "a"}
+	v2.Prepare()
+	s.Update(v2)
+
+	if len(h.updates) != 2 {
+		t.Fatalf("expected 2 update notifications, actual: %d (%v)", len(h.updates), h.updates)
+	}
+	if h.updates[0] != "new:a" {
+		t.Error("expected first notification to report a new app, actual: ", h.updates[0])
+	}
+	if h.updates[1] != "changed:a:1.0.0->1.1.0" {
+		t.Error("expected second notification to report the version change, actual: ", h.updates[1])
+	}
+
+	c := s.Trim(v2.ExactUpdate.Add(time.Second))
+	if c != 1 {
+		t.Fatal("expected trim to remove 1 version, actual: ", c)
+	}
+	if len(h.trims) != 1 || h.trims[0] != "a" {
+		t.Fatal("expected a trim notification for host a, actual: ", h.trims)
+	}
+}
+
+// USTestPrereleaseOrdering covers Latest and VersionsInRange when a
+// pre-release and its base release are both known: a pre-release ranks
+// below its release regardless of report order, so it must never win
+// Latest or satisfy a constraint the release alone would satisfy.
+func USTestPrereleaseOrdering(s store.UpdateStore, t *testing.T) {
+	stable := version.Version{App: "app1", Ver: "2.0.0", Host: "a"}
+	stable.Prepare()
+	s.Update(stable)
+
+	pre := version.Version{App: "app1", Ver: "2.0.0-rc1", Host: "b"}
+	pre.Prepare()
+	s.Update(pre)
+
+	latest, ok := s.Latest("app1")
+	if !ok {
+		t.Fatal("expected ok")
+	}
+	if latest.Host != "a" {
+		t.Error("expected the stable release to be Latest even though its pre-release was reported after it, actual host: ", latest.Host)
+	}
+
+	for _, v := range s.VersionsInRange("app1", ">=2.0.0") {
+		if v.Host == "b" {
+			t.Error("expected 2.0.0-rc1 to not satisfy >=2.0.0")
+		}
+	}
+}
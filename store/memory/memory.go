@@ -0,0 +1,248 @@
+// Package memory implements store.UpdateStore with an in-process map. It
+// is the default backend for the partisci daemon when no PostgreSQL DSN
+// is configured, and is cheap enough to run unconditionally in the
+// sharedtest suite.
+package memory
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"partisci/store"
+	"partisci/version"
+)
+
+// Store is a store.UpdateStore backed by an in-memory map. Its contents
+// do not survive a restart.
+type Store struct {
+	mu        sync.Mutex
+	versions  map[string]version.Version
+	clearedAt time.Time
+	hook      store.NotificationHook
+}
+
+// New returns an empty, ready-to-use Store.
+func New() *Store {
+	return &Store{versions: make(map[string]version.Version)}
+}
+
+// key identifies the single Version slot a report for appId/host/instance occupies.
+func key(appId, host string, instance uint16) string {
+	return appId + "\x00" + host + "\x00" + strconv.Itoa(int(instance))
+}
+
+// Update stores v, replacing any prior Version for the same
+// app/host/instance. Updates older than the most recent Clear are
+// discarded. If a NotificationHook is registered, its Updated method is
+// called with the replaced Version while the store lock is still held,
+// so it always sees exactly the transition this call made.
+func (s *Store) Update(v version.Version) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if v.ExactUpdate.Before(s.clearedAt) {
+		return nil
+	}
+
+	k := key(v.Id, v.Host, v.Instance)
+	prev, existed := s.versions[k]
+	s.versions[k] = v
+
+	if s.hook != nil {
+		s.hook.Updated(prev, v, existed)
+	}
+	return nil
+}
+
+// App returns an AppSummary for the given AppId.
+func (s *Store) App(appId string) (as version.AppSummary, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hosts := map[string]bool{}
+	for _, v := range s.versions {
+		if v.Id == appId {
+			hosts[v.Host] = true
+		}
+	}
+	if len(hosts) == 0 {
+		return as, false
+	}
+	return version.AppSummary{AppId: appId, HostCount: len(hosts)}, true
+}
+
+// Apps returns summary information about each application, based on the
+// known Versions.
+func (s *Store) Apps() (vs []version.AppSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hostsByApp := map[string]map[string]bool{}
+	for _, v := range s.versions {
+		if hostsByApp[v.Id] == nil {
+			hostsByApp[v.Id] = map[string]bool{}
+		}
+		hostsByApp[v.Id][v.Host] = true
+	}
+	for appId, hosts := range hostsByApp {
+		vs = append(vs, version.AppSummary{AppId: appId, HostCount: len(hosts)})
+	}
+	return vs
+}
+
+// Host returns a HostSummary for the given Host.
+func (s *Store) Host(host string) (hs version.HostSummary, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	apps := map[string]bool{}
+	for _, v := range s.versions {
+		if v.Host == host {
+			apps[v.Id] = true
+		}
+	}
+	if len(apps) == 0 {
+		return hs, false
+	}
+	return version.HostSummary{Host: host, AppCount: len(apps)}, true
+}
+
+// Hosts returns summary information about each host, based on the known
+// Versions.
+func (s *Store) Hosts() (vs []version.HostSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	appsByHost := map[string]map[string]bool{}
+	for _, v := range s.versions {
+		if appsByHost[v.Host] == nil {
+			appsByHost[v.Host] = map[string]bool{}
+		}
+		appsByHost[v.Host][v.Id] = true
+	}
+	for host, apps := range appsByHost {
+		vs = append(vs, version.HostSummary{Host: host, AppCount: len(apps)})
+	}
+	return vs
+}
+
+// Versions returns full Version structs where their values match app_id,
+// host and ver. Zero length strings are considered a match for all
+// Versions.
+func (s *Store) Versions(appId string, host string, ver string) (vs []version.Version) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range s.versions {
+		if appId != "" && v.Id != appId {
+			continue
+		}
+		if host != "" && v.Host != host {
+			continue
+		}
+		if ver != "" && v.Ver != ver {
+			continue
+		}
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+// VersionsInRange returns Versions for appId whose semver falls within
+// constraint. Versions that did not parse as semver are excluded.
+func (s *Store) VersionsInRange(appId string, constraint string) (vs []version.Version) {
+	c, err := version.ParseConstraint(constraint)
+	if err != nil {
+		return nil
+	}
+	for _, v := range s.Versions(appId, "", "") {
+		v.ParseSemver()
+		if c.Contains(v) {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// UnparseableVersions returns Versions for appId whose Ver did not parse
+// as semver.
+func (s *Store) UnparseableVersions(appId string) (vs []version.Version) {
+	for _, v := range s.Versions(appId, "", "") {
+		v.ParseSemver()
+		if !v.SemverOK {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// Latest returns the Version with the highest-ranked semver known for
+// appId.
+func (s *Store) Latest(appId string) (latest version.Version, ok bool) {
+	for _, v := range s.Versions(appId, "", "") {
+		v.ParseSemver()
+		if !v.SemverOK {
+			continue
+		}
+		if !ok || version.CompareSemver(v, latest) > 0 {
+			latest, ok = v, true
+		}
+	}
+	return latest, ok
+}
+
+// Advisories returns the most recently reported Warnings and Deprecated
+// flag for each Ver of appId.
+func (s *Store) Advisories(appId string) (as []version.Advisory) {
+	s.mu.Lock()
+	latest := map[string]version.Version{}
+	for _, v := range s.versions {
+		if v.Id != appId {
+			continue
+		}
+		if cur, ok := latest[v.Ver]; !ok || v.ExactUpdate.After(cur.ExactUpdate) {
+			latest[v.Ver] = v
+		}
+	}
+	s.mu.Unlock()
+
+	for ver, v := range latest {
+		as = append(as, version.Advisory{AppId: appId, Ver: ver, Warnings: v.Warnings, Deprecated: v.Deprecated})
+	}
+	return as
+}
+
+// Clear empties the Store.
+func (s *Store) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.versions = make(map[string]version.Version)
+	s.clearedAt = time.Now()
+}
+
+// Trim removes old versions. If a NotificationHook is registered, its
+// Trimmed method is called for each Version removed.
+func (s *Store) Trim(t time.Time) (c uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, v := range s.versions {
+		if v.ExactUpdate.Before(t) {
+			delete(s.versions, k)
+			c++
+			if s.hook != nil {
+				s.hook.Trimmed(v)
+			}
+		}
+	}
+	return c
+}
+
+// SetNotificationHook registers h to be called transactionally from
+// within Update and Trim. A nil h disables notifications.
+func (s *Store) SetNotificationHook(h store.NotificationHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hook = h
+}
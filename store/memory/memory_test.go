@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"testing"
+
+	"partisci/sharedtest"
+)
+
+// Unlike store/postgres, Store needs no external fixture, so these run
+// unconditionally under a plain `go test ./...`.
+
+func TestAppSummary(t *testing.T) {
+	sharedtest.USTestAppSummary(New(), t)
+}
+
+func TestHostSummary(t *testing.T) {
+	sharedtest.USTestHostSummary(New(), t)
+}
+
+func TestVersionsInRange(t *testing.T) {
+	sharedtest.USTestVersionsInRange(New(), t)
+}
+
+func TestPrereleaseOrdering(t *testing.T) {
+	sharedtest.USTestPrereleaseOrdering(New(), t)
+}
+
+func TestAdvisories(t *testing.T) {
+	sharedtest.USTestAdvisories(New(), t)
+}
+
+func TestClearUpdate(t *testing.T) {
+	sharedtest.USTestClearUpdate(New(), t)
+}
+
+func TestTrim(t *testing.T) {
+	sharedtest.USTestTrim(New(), t)
+}
+
+func TestNotificationHook(t *testing.T) {
+	sharedtest.USTestNotificationHook(New(), t)
+}
@@ -0,0 +1,342 @@
+// Package postgres implements store.UpdateStore against PostgreSQL.
+//
+// Versions, apps, and hosts are kept as normalized tables so that App,
+// Host, Apps, and Hosts are answered with aggregate queries rather than
+// in-memory rollups, and Trim removes expired versions with a single
+// indexed DELETE before pruning apps and hosts left without versions.
+package postgres
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"partisci/store"
+	"partisci/version"
+
+	"github.com/lib/pq"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS apps (
+	app_id TEXT PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS hosts (
+	host TEXT PRIMARY KEY
+);
+
+CREATE TABLE IF NOT EXISTS versions (
+	app_id       TEXT NOT NULL REFERENCES apps(app_id),
+	host         TEXT NOT NULL REFERENCES hosts(host),
+	ver          TEXT NOT NULL,
+	instance     INTEGER NOT NULL DEFAULT 0,
+	host_ip      TEXT,
+	last_update  BIGINT NOT NULL,
+	exact_update TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (app_id, host, instance)
+);
+
+CREATE INDEX IF NOT EXISTS versions_app_host_ver_idx ON versions (app_id, host, ver);
+CREATE INDEX IF NOT EXISTS versions_exact_update_idx ON versions (exact_update);
+`
+
+// migrations runs in order after schema, each guarded so it is safe to
+// run against a database already on a later version: every step a
+// deployment might not have applied yet, rather than folded into the
+// initial CREATE TABLE.
+var migrations = []string{
+	`ALTER TABLE versions ADD COLUMN IF NOT EXISTS warnings TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE versions ADD COLUMN IF NOT EXISTS deprecated BOOLEAN NOT NULL DEFAULT FALSE`,
+}
+
+// Store is a store.UpdateStore backed by a PostgreSQL database.
+type Store struct {
+	db        *sql.DB
+	clearedAt time.Time
+	hook      store.NotificationHook
+}
+
+// New opens dsn (a PostgreSQL connection string) and ensures the schema
+// exists, returning a ready-to-use Store.
+func New(dsn string) (*Store, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+	for _, m := range migrations {
+		if _, err := db.Exec(m); err != nil {
+			return nil, err
+		}
+	}
+	return &Store{db: db}, nil
+}
+
+// Update stores v and updates the apps and hosts tables. Updates older
+// than the most recent Clear are discarded. If a NotificationHook is
+// registered, its Updated method is called with the prior Version for
+// v's app/host/instance once the upsert succeeds. The read of that
+// prior Version and the upsert run inside one transaction, serialized
+// by a per-key advisory lock, so concurrent Updates racing on the same
+// app/host/instance can never both observe existed=false.
+func (s *Store) Update(v version.Version) (err error) {
+	if v.ExactUpdate.Before(s.clearedAt) {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	lockKey := v.Id + "\x00" + v.Host + "\x00" + strconv.Itoa(int(v.Instance))
+	if _, err = tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1), 0)`, lockKey); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`INSERT INTO apps (app_id) VALUES ($1) ON CONFLICT DO NOTHING`, v.Id); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(`INSERT INTO hosts (host) VALUES ($1) ON CONFLICT DO NOTHING`, v.Host); err != nil {
+		return err
+	}
+
+	var prev version.Version
+	var existed bool
+	if s.hook != nil {
+		row := tx.QueryRow(`
+			SELECT app_id, host, ver, instance, host_ip, last_update, exact_update, warnings, deprecated
+			FROM versions WHERE app_id = $1 AND host = $2 AND instance = $3
+		`, v.Id, v.Host, v.Instance)
+		if p, scanErr := scanVersion(row); scanErr == nil {
+			prev, existed = p, true
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO versions (app_id, host, ver, instance, host_ip, last_update, exact_update, warnings, deprecated)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (app_id, host, instance) DO UPDATE SET
+			ver          = EXCLUDED.ver,
+			host_ip      = EXCLUDED.host_ip,
+			last_update  = EXCLUDED.last_update,
+			exact_update = EXCLUDED.exact_update,
+			warnings     = EXCLUDED.warnings,
+			deprecated   = EXCLUDED.deprecated
+	`, v.Id, v.Host, v.Ver, v.Instance, v.HostIP, v.LastUpdate, v.ExactUpdate, pq.Array(v.Warnings), v.Deprecated)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	if s.hook != nil {
+		s.hook.Updated(prev, v, existed)
+	}
+	return nil
+}
+
+// SetNotificationHook registers h to be called transactionally from
+// within Update and Trim. A nil h disables notifications.
+func (s *Store) SetNotificationHook(h store.NotificationHook) {
+	s.hook = h
+}
+
+// App returns an AppSummary for the given AppId.
+func (s *Store) App(AppId string) (as version.AppSummary, ok bool) {
+	row := s.db.QueryRow(`SELECT count(DISTINCT host) FROM versions WHERE app_id = $1`, AppId)
+	var count int
+	if err := row.Scan(&count); err != nil || count == 0 {
+		return as, false
+	}
+	return version.AppSummary{AppId: AppId, HostCount: count}, true
+}
+
+// Apps returns summary information about each application, based on the
+// known Versions.
+func (s *Store) Apps() (vs []version.AppSummary) {
+	rows, err := s.db.Query(`SELECT app_id, count(DISTINCT host) FROM versions GROUP BY app_id`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var as version.AppSummary
+		if err := rows.Scan(&as.AppId, &as.HostCount); err != nil {
+			continue
+		}
+		vs = append(vs, as)
+	}
+	return vs
+}
+
+// Host returns a HostSummary for the given Host.
+func (s *Store) Host(Host string) (hs version.HostSummary, ok bool) {
+	row := s.db.QueryRow(`SELECT count(DISTINCT app_id) FROM versions WHERE host = $1`, Host)
+	var count int
+	if err := row.Scan(&count); err != nil || count == 0 {
+		return hs, false
+	}
+	return version.HostSummary{Host: Host, AppCount: count}, true
+}
+
+// Hosts returns summary information about each host, based on the known
+// Versions.
+func (s *Store) Hosts() (vs []version.HostSummary) {
+	rows, err := s.db.Query(`SELECT host, count(DISTINCT app_id) FROM versions GROUP BY host`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hs version.HostSummary
+		if err := rows.Scan(&hs.Host, &hs.AppCount); err != nil {
+			continue
+		}
+		vs = append(vs, hs)
+	}
+	return vs
+}
+
+// Versions returns full Version structs where their values match app_id,
+// host and ver. Zero length strings are considered a match for all
+// Versions.
+func (s *Store) Versions(app_id string, host string, ver string) (vs []version.Version) {
+	rows, err := s.db.Query(`
+		SELECT app_id, host, ver, instance, host_ip, last_update, exact_update, warnings, deprecated
+		FROM versions
+		WHERE ($1 = '' OR app_id = $1)
+		  AND ($2 = '' OR host = $2)
+		  AND ($3 = '' OR ver = $3)
+	`, app_id, host, ver)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			continue
+		}
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+// VersionsInRange returns Versions for appId whose semver falls within
+// constraint. Versions that did not parse as semver are excluded; see
+// UnparseableVersions.
+func (s *Store) VersionsInRange(appId string, constraint string) (vs []version.Version) {
+	c, err := version.ParseConstraint(constraint)
+	if err != nil {
+		return nil
+	}
+	for _, v := range s.Versions(appId, "", "") {
+		v.ParseSemver()
+		if c.Contains(v) {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// UnparseableVersions returns Versions for appId whose Ver did not parse
+// as semver.
+func (s *Store) UnparseableVersions(appId string) (vs []version.Version) {
+	for _, v := range s.Versions(appId, "", "") {
+		v.ParseSemver()
+		if !v.SemverOK {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// Latest returns the Version with the highest-ranked semver known for
+// appId.
+func (s *Store) Latest(appId string) (latest version.Version, ok bool) {
+	for _, v := range s.Versions(appId, "", "") {
+		v.ParseSemver()
+		if !v.SemverOK {
+			continue
+		}
+		if !ok || version.CompareSemver(v, latest) > 0 {
+			latest, ok = v, true
+		}
+	}
+	return latest, ok
+}
+
+// Advisories returns the most recently reported Warnings and Deprecated
+// flag for each Ver of appId.
+func (s *Store) Advisories(appId string) (as []version.Advisory) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT ON (ver) ver, warnings, deprecated
+		FROM versions
+		WHERE app_id = $1
+		ORDER BY ver, exact_update DESC
+	`, appId)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a := version.Advisory{AppId: appId}
+		if err := rows.Scan(&a.Ver, pq.Array(&a.Warnings), &a.Deprecated); err != nil {
+			continue
+		}
+		as = append(as, a)
+	}
+	return as
+}
+
+// Clear empties the Store.
+func (s *Store) Clear() {
+	s.db.Exec(`TRUNCATE versions, apps, hosts`)
+	s.clearedAt = time.Now()
+}
+
+// Trim removes old versions. If a NotificationHook is registered, its
+// Trimmed method is called for each Version removed, based on the same
+// DELETE that computes c, so the notified rows and c always agree.
+func (s *Store) Trim(t time.Time) (c uint64) {
+	rows, err := s.db.Query(`
+		DELETE FROM versions WHERE exact_update < $1
+		RETURNING app_id, host, ver, instance, host_ip, last_update, exact_update, warnings, deprecated
+	`, t)
+	if err == nil {
+		for rows.Next() {
+			v, scanErr := scanVersion(rows)
+			if scanErr != nil {
+				continue
+			}
+			c++
+			if s.hook != nil {
+				s.hook.Trimmed(v)
+			}
+		}
+		rows.Close()
+	}
+	s.db.Exec(`DELETE FROM apps WHERE app_id NOT IN (SELECT DISTINCT app_id FROM versions)`)
+	s.db.Exec(`DELETE FROM hosts WHERE host NOT IN (SELECT DISTINCT host FROM versions)`)
+	return c
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanVersion(row scanner) (v version.Version, err error) {
+	err = row.Scan(&v.Id, &v.Host, &v.Ver, &v.Instance, &v.HostIP, &v.LastUpdate, &v.ExactUpdate,
+		pq.Array(&v.Warnings), &v.Deprecated)
+	return v, err
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"os"
+	"testing"
+
+	"partisci/sharedtest"
+)
+
+// Integration tests run against a real PostgreSQL database named by
+// PARTISCI_POSTGRES_TEST_DSN, e.g.:
+//
+//	PARTISCI_POSTGRES_TEST_DSN="postgres://localhost/partisci_test?sslmode=disable" go test ./store/postgres
+//
+// They are skipped when that variable is unset.
+func newTestStore(t *testing.T) *Store {
+	dsn := os.Getenv("PARTISCI_POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("PARTISCI_POSTGRES_TEST_DSN not set; skipping PostgreSQL integration tests")
+	}
+	s, err := New(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Clear()
+	return s
+}
+
+func TestAppSummary(t *testing.T) {
+	sharedtest.USTestAppSummary(newTestStore(t), t)
+}
+
+func TestHostSummary(t *testing.T) {
+	sharedtest.USTestHostSummary(newTestStore(t), t)
+}
+
+func TestVersionsInRange(t *testing.T) {
+	sharedtest.USTestVersionsInRange(newTestStore(t), t)
+}
+
+func TestPrereleaseOrdering(t *testing.T) {
+	sharedtest.USTestPrereleaseOrdering(newTestStore(t), t)
+}
+
+func TestAdvisories(t *testing.T) {
+	sharedtest.USTestAdvisories(newTestStore(t), t)
+}
+
+func TestClearUpdate(t *testing.T) {
+	sharedtest.USTestClearUpdate(newTestStore(t), t)
+}
+
+func TestTrim(t *testing.T) {
+	sharedtest.USTestTrim(newTestStore(t), t)
+}
+
+func TestNotificationHook(t *testing.T) {
+	sharedtest.USTestNotificationHook(newTestStore(t), t)
+}
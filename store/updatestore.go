@@ -10,6 +10,19 @@ import (
 	"time"
 )
 
+// NotificationHook receives the raw state transitions observed during
+// Update and Trim, for translation into notifier.Events by whichever
+// hook implementation the daemon configures. See partisci/notifier.Hook.
+type NotificationHook interface {
+	// Updated is called after Update persists v. prev is the previously
+	// stored Version for the same app/host/instance, and existed
+	// reports whether one was found.
+	Updated(prev, v version.Version, existed bool)
+
+	// Trimmed is called by Trim for each Version it removes.
+	Trimmed(v version.Version)
+}
+
 // UpdateStore defines an interface for persisting application version information.
 type UpdateStore interface {
 	// Update stores a Version and updates app and host summaries.
@@ -37,11 +50,34 @@ type UpdateStore interface {
 	// and ver. Zero length strings are considered a match for all Versions.
 	Versions(app_id string, host string, ver string) (vs []version.Version)
 
+	// VersionsInRange returns Versions for appId whose semver falls within
+	// constraint (see version.ParseConstraint for accepted syntax, e.g.
+	// ">=1.2.0 <2.0.0" or "^1.2"). Versions that did not parse as semver
+	// are excluded; see UnparseableVersions.
+	VersionsInRange(appId string, constraint string) (vs []version.Version)
+
+	// UnparseableVersions returns Versions for appId whose Ver did not
+	// parse as semver, and so are excluded from VersionsInRange results.
+	UnparseableVersions(appId string) (vs []version.Version)
+
+	// Latest returns the Version with the highest-ranked semver known for
+	// appId. The value of ok follows map indexing conventions: true if a
+	// parseable Version exists for appId, false otherwise.
+	Latest(appId string) (v version.Version, ok bool)
+
+	// Advisories returns the most recently reported Warnings and
+	// Deprecated flag for each Ver of appId.
+	Advisories(appId string) (as []version.Advisory)
+
 	// Clear empties the MemoryStore.
 	Clear()
 
 	// Trim removes old versions.
 	Trim(t time.Time) (c uint64)
+
+	// SetNotificationHook registers h to be called transactionally from
+	// within Update and Trim. A nil h disables notifications.
+	SetNotificationHook(h NotificationHook)
 }
 
 func USTestAppSummary(s UpdateStore, t *testing.T) {
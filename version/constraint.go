@@ -0,0 +1,122 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint is a parsed semver range: optionally an inclusive or
+// exclusive minimum bound and, optionally, an inclusive or exclusive
+// maximum bound.
+type Constraint struct {
+	Min          Version
+	MinInclusive bool
+	hasMin       bool
+	Max          Version
+	MaxInclusive bool
+	hasMax       bool
+}
+
+// ParseConstraint parses a semver range expression such as ">=1.2.0
+// <2.0.0" into a Constraint. The caret shorthand "^1.2" is also accepted,
+// equivalent to ">=1.2.0 <2.0.0".
+func ParseConstraint(s string) (c Constraint, err error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "^") {
+		return parseCaret(strings.TrimSpace(s[1:]))
+	}
+	for _, term := range strings.Fields(s) {
+		var bound Version
+		switch {
+		case strings.HasPrefix(term, ">="):
+			bound, err = parseBound(term[2:])
+			c.Min, c.MinInclusive, c.hasMin = bound, true, true
+		case strings.HasPrefix(term, ">"):
+			bound, err = parseBound(term[1:])
+			c.Min, c.MinInclusive, c.hasMin = bound, false, true
+		case strings.HasPrefix(term, "<="):
+			bound, err = parseBound(term[2:])
+			c.Max, c.MaxInclusive, c.hasMax = bound, true, true
+		case strings.HasPrefix(term, "<"):
+			bound, err = parseBound(term[1:])
+			c.Max, c.MaxInclusive, c.hasMax = bound, false, true
+		default:
+			err = fmt.Errorf("version: unrecognized constraint term %q", term)
+		}
+		if err != nil {
+			return Constraint{}, err
+		}
+	}
+	return c, nil
+}
+
+func parseCaret(s string) (c Constraint, err error) {
+	min, err := parseBound(s)
+	if err != nil {
+		return Constraint{}, err
+	}
+	c.Min, c.MinInclusive, c.hasMin = min, true, true
+	c.Max, c.MaxInclusive, c.hasMax = Version{Major: min.Major + 1}, false, true
+	return c, nil
+}
+
+func parseBound(s string) (Version, error) {
+	v := Version{Ver: s}
+	v.ParseSemver()
+	if !v.SemverOK {
+		return Version{}, fmt.Errorf("version: %q is not a valid semver", s)
+	}
+	return v, nil
+}
+
+// Contains reports whether v falls within the constraint. A Version that
+// did not parse as semver never matches.
+func (c Constraint) Contains(v Version) bool {
+	if !v.SemverOK {
+		return false
+	}
+	if c.hasMin {
+		if cmp := CompareSemver(v, c.Min); cmp < 0 || (cmp == 0 && !c.MinInclusive) {
+			return false
+		}
+	}
+	if c.hasMax {
+		if cmp := CompareSemver(v, c.Max); cmp > 0 || (cmp == 0 && !c.MaxInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// CompareSemver compares the parsed semver of a and b, returning a
+// negative number if a < b, zero if equal, and a positive number if a > b.
+// A Pre-release ranks below its base release, per normal semver
+// precedence.
+func CompareSemver(a, b Version) int {
+	if a.Major != b.Major {
+		return a.Major - b.Major
+	}
+	if a.Minor != b.Minor {
+		return a.Minor - b.Minor
+	}
+	if a.Patch != b.Patch {
+		return a.Patch - b.Patch
+	}
+	return comparePre(a.Pre, b.Pre)
+}
+
+// comparePre orders two Pre fields: a release (empty Pre) outranks any
+// pre-release of the same Major.Minor.Patch, and two pre-release tags
+// are compared lexically.
+func comparePre(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	default:
+		return strings.Compare(a, b)
+	}
+}
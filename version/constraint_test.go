@@ -0,0 +1,71 @@
+package version
+
+import "testing"
+
+func TestCompareSemverPrerelease(t *testing.T) {
+	release := Version{Ver: "2.0.0"}
+	release.ParseSemver()
+	pre := Version{Ver: "2.0.0-rc1"}
+	pre.ParseSemver()
+
+	if CompareSemver(release, pre) <= 0 {
+		t.Error("expected a release to rank higher than its pre-release")
+	}
+	if CompareSemver(pre, release) >= 0 {
+		t.Error("expected a pre-release to rank lower than its release")
+	}
+	if CompareSemver(release, release) != 0 {
+		t.Error("expected equal versions to compare equal")
+	}
+}
+
+func TestParseConstraint(t *testing.T) {
+	c, err := ParseConstraint(">=1.2.0 <2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := Version{Ver: "1.5.0"}
+	in.ParseSemver()
+	if !c.Contains(in) {
+		t.Error("expected 1.5.0 to satisfy >=1.2.0 <2.0.0")
+	}
+
+	out := Version{Ver: "2.0.0"}
+	out.ParseSemver()
+	if c.Contains(out) {
+		t.Error("expected 2.0.0 to not satisfy >=1.2.0 <2.0.0")
+	}
+}
+
+func TestConstraintExcludesPrerelease(t *testing.T) {
+	c, err := ParseConstraint(">=2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pre := Version{Ver: "2.0.0-rc1"}
+	pre.ParseSemver()
+	if c.Contains(pre) {
+		t.Error("expected 2.0.0-rc1 to not satisfy >=2.0.0; a pre-release ranks below its release")
+	}
+}
+
+func TestParseCaretConstraint(t *testing.T) {
+	c, err := ParseConstraint("^1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := Version{Ver: "1.9.0"}
+	in.ParseSemver()
+	if !c.Contains(in) {
+		t.Error("expected 1.9.0 to satisfy ^1.2")
+	}
+
+	out := Version{Ver: "2.0.0"}
+	out.ParseSemver()
+	if c.Contains(out) {
+		t.Error("expected 2.0.0 to not satisfy ^1.2")
+	}
+}
@@ -2,18 +2,106 @@ package version
 
 import (
 	"encoding/json"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type Version struct {
-	Id         string `json:"id"`
-	App        string `json:"app"`
-	Version    string `json:"version,omitempty"`
-	Host       string `json:"host,omitempty"`
-	Instance   uint16 `json:"instance,omitempty"`
-	HostIP     string `json:"host_ip,omitempty"`
-	LastUpdate int64  `json:"last_update,omitempty"`
+	Id          string    `json:"id"`
+	App         string    `json:"app"`
+	Ver         string    `json:"ver,omitempty"`
+	Host        string    `json:"host,omitempty"`
+	Instance    uint16    `json:"instance,omitempty"`
+	HostIP      string    `json:"host_ip,omitempty"`
+	LastUpdate  int64     `json:"last_update,omitempty"`
+	ExactUpdate time.Time `json:"-"`
+
+	// Major, Minor, Patch and Pre hold the semver components parsed from
+	// Ver by Prepare. SemverOK is false when Ver did not match the
+	// expected pattern, in which case the other fields are zero.
+	Major    int    `json:"major,omitempty"`
+	Minor    int    `json:"minor,omitempty"`
+	Patch    int    `json:"patch,omitempty"`
+	Pre      string `json:"pre,omitempty"`
+	SemverOK bool   `json:"-"`
+
+	// Warnings are free-form advisory messages a client may attach to a
+	// report (e.g. "this release is archived, upgrade to 0.9.7").
+	// Deprecated marks the reported Ver as no longer recommended.
+	Warnings   []string `json:"warnings,omitempty"`
+	Deprecated bool     `json:"deprecated,omitempty"`
+}
+
+// Advisory is an aggregated view of the most recent warnings reported for
+// a single app/version pair.
+type Advisory struct {
+	AppId      string   `json:"app_id"`
+	Ver        string   `json:"ver"`
+	Warnings   []string `json:"warnings,omitempty"`
+	Deprecated bool     `json:"deprecated,omitempty"`
+}
+
+// maxWarnings caps the number of Warnings accepted from a client report.
+const maxWarnings = 16
+
+// dedupeWarnings drops empty and repeated entries from warnings,
+// preserving order, and caps the result at maxWarnings.
+func dedupeWarnings(warnings []string) []string {
+	seen := make(map[string]bool, len(warnings))
+	out := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		if w == "" || seen[w] {
+			continue
+		}
+		seen[w] = true
+		out = append(out, w)
+		if len(out) == maxWarnings {
+			break
+		}
+	}
+	return out
+}
+
+// semverPattern is modeled on gopkg.in's version pattern: an optional "v"
+// prefix, one to three dot-separated numeric components, and an optional
+// "-"-prefixed pre-release tag such as "-unstable" or "-rc1".
+var semverPattern = regexp.MustCompile(`^v?(0|[1-9][0-9]*)(?:\.(0|[1-9][0-9]*))?(?:\.(0|[1-9][0-9]*))?(-[a-z0-9]+)?$`)
+
+// ParseSemver populates Major, Minor, Patch, Pre and SemverOK from Ver. It
+// is called automatically by Prepare.
+func (v *Version) ParseSemver() {
+	m := semverPattern.FindStringSubmatch(v.Ver)
+	if m == nil {
+		v.Major, v.Minor, v.Patch, v.Pre, v.SemverOK = 0, 0, 0, "", false
+		return
+	}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	v.Pre = m[4]
+	v.SemverOK = true
+}
+
+// AppSummary describes the hosts known to be running a single application.
+type AppSummary struct {
+	AppId     string `json:"app_id"`
+	HostCount int    `json:"host_count"`
+}
+
+// HostSummary describes the applications known to be running on a single host.
+type HostSummary struct {
+	Host     string `json:"host"`
+	AppCount int    `json:"app_count"`
+}
+
+// Prepare finalizes a Version received from a client: it derives Id from
+// App, stamps ExactUpdate with the current time, and parses Ver as semver.
+func (v *Version) Prepare() {
+	v.Id = AppToID(v.App)
+	v.ExactUpdate = time.Now()
+	v.ParseSemver()
 }
 
 func safeRunes(r rune) rune {
@@ -40,5 +128,6 @@ func ParsePacket(host string, b []byte) (v Version, err error) {
 		return v, err
 	}
 	v.Id = AppToID(v.App)
+	v.Warnings = dedupeWarnings(v.Warnings)
 	return
 }
@@ -0,0 +1,49 @@
+package version
+
+import "testing"
+
+func TestParseSemver(t *testing.T) {
+	cases := []struct {
+		ver                            string
+		wantOK                         bool
+		wantMajor, wantMinor, wantPatch int
+		wantPre                        string
+	}{
+		{"1.2.3", true, 1, 2, 3, ""},
+		{"v1.2.3", true, 1, 2, 3, ""},
+		{"1.2.3-rc1", true, 1, 2, 3, "-rc1"},
+		{"not-a-semver", false, 0, 0, 0, ""},
+	}
+	for _, c := range cases {
+		v := Version{Ver: c.ver}
+		v.ParseSemver()
+		if v.SemverOK != c.wantOK {
+			t.Errorf("%q: SemverOK = %v, want %v", c.ver, v.SemverOK, c.wantOK)
+			continue
+		}
+		if !c.wantOK {
+			continue
+		}
+		if v.Major != c.wantMajor || v.Minor != c.wantMinor || v.Patch != c.wantPatch || v.Pre != c.wantPre {
+			t.Errorf("%q: got %d.%d.%d%s, want %d.%d.%d%s",
+				c.ver, v.Major, v.Minor, v.Patch, v.Pre, c.wantMajor, c.wantMinor, c.wantPatch, c.wantPre)
+		}
+	}
+}
+
+func TestAppToID(t *testing.T) {
+	if id := AppToID("My App!"); id != "my_app_" {
+		t.Errorf("got %q, want %q", id, "my_app_")
+	}
+}
+
+func TestParsePacketDedupesWarnings(t *testing.T) {
+	b := []byte(`{"app":"app1","ver":"1.0.0","warnings":["a","a","","b"]}`)
+	v, err := ParsePacket("10.0.0.1", b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Warnings) != 2 || v.Warnings[0] != "a" || v.Warnings[1] != "b" {
+		t.Errorf("got %v, want [a b]", v.Warnings)
+	}
+}